@@ -15,13 +15,23 @@
 package application
 
 import (
+	"context"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
-// NOTE: StorageGc will continue GC until program exits
+// gcLeaseTTL bounds how long the GC leader's lease stays valid without
+// renewal. The leader renews at half this interval, so a single missed
+// heartbeat never lets the lease lapse under the holder's feet.
+const gcLeaseTTL = 30 * time.Second
+
+// NOTE: StorageGc will continue GC until program exits. Only the current
+// leader (the holder of the fenced lease from MetaMgr.HoldGcLock) performs
+// the cross-project ClearExpiredResults sweep; every other host just retries
+// leadership on the next tick while running SessionGc in the meantime.
 func (app *App) StorageGc() {
 	// check locking info exists in table
 	err := app.MetaMgr.InitGcLockIfNecessary()
@@ -30,33 +40,79 @@ func (app *App) StorageGc() {
 		return
 	}
 
-	// Question: should we reuse the SessionCheckInterval ? it maybe too often
-	ticker := time.NewTicker(app.Conf.SessionCheckInterval)
-	owner := app.Conf.PartyCode
-	if host := os.Getenv("HOSTNAME"); host != "" {
-		owner = host
-	} else {
+	owner := os.Getenv("HOSTNAME")
+	if owner == "" {
+		owner = app.Conf.PartyCode
 		logrus.Warnf("cannot find HOSTNAME env, using party code as owner")
 	}
+
+	ticker := time.NewTicker(app.Conf.SessionCheckInterval)
 	for {
 		<-ticker.C
+		app.runGcLeaderCycle(owner)
+	}
+}
 
-		// hold lock or continue for retry
-		err := app.MetaMgr.HoldGcLock(owner, app.Conf.SessionCheckInterval)
-		if err != nil {
-			continue
-		}
+// runGcLeaderCycle makes one attempt at acquiring (or re-acquiring) GC
+// leadership and, if successful, runs a single ClearExpiredResults sweep
+// under a heartbeat-backed lease: a background goroutine renews the lease's
+// fencing token at TTL/2, and the sweep's context is cancelled the instant a
+// renewal fails, so a partitioned former leader can never keep deleting with
+// a stale token.
+func (app *App) runGcLeaderCycle(owner string) {
+	lease, err := app.MetaMgr.HoldGcLock(owner, gcLeaseTTL)
+	if err != nil {
+		// someone else holds the lease, or the store is unavailable; retry
+		// on the next tick.
+		return
+	}
+	app.GcMetrics.RecordAcquisition()
 
-		// scan table to get all expired ids
-		err = app.MetaMgr.ClearExpiredResults(app.Conf.SessionExpireTime)
-		if err != nil {
-			logrus.Warnf("GC err: %s", err.Error())
+	var token uint64
+	atomic.StoreUint64(&token, lease.Token)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(gcLeaseTTL / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				renewed, err := app.MetaMgr.RenewGcLock(owner, atomic.LoadUint64(&token), gcLeaseTTL)
+				if err != nil {
+					logrus.Warnf("gc lease renewal failed, stepping down as leader: %v", err)
+					app.GcMetrics.RecordExpiration()
+					cancel()
+					return
+				}
+				atomic.StoreUint64(&token, renewed.Token)
+				app.GcMetrics.RecordRenewal()
+			}
 		}
+	}()
 
+	// scan table to get all expired ids, stopping as soon as the heartbeat
+	// goroutine cancels ctx out from under a lost lease.
+	reaped, err := app.MetaMgr.ClearExpiredResults(ctx, app.Conf.SessionExpireTime)
+	cancel()
+	<-heartbeatDone
+	if err != nil && ctx.Err() == nil {
+		logrus.Warnf("GC err: %s", err.Error())
 	}
+	app.GcMetrics.RecordReaped(reaped)
 }
 
-// NOTE: SessionGc will continue GC until program exits
+// NOTE: SessionGc will continue GC until program exits. Unlike StorageGc,
+// this is the "sweeper" role: every host runs it unconditionally, each
+// reaping only its own locally-owned session artifacts -- the in-memory
+// app.Sessions entry and, via DeleteSession, that session's engine-side
+// temp files -- for sessions that MetaMgr.CheckIdCanceled reports as
+// canceled; no other session state is checked. Sweeping this way stays
+// parallel across hosts instead of funneling through the single GC leader.
 func (app *App) SessionGc() {
 	ticker := time.NewTicker(app.Conf.SessionCheckInterval)
 	for {
@@ -74,8 +130,12 @@ func (app *App) SessionGc() {
 			continue
 		}
 		for _, id := range canceledIds {
+			// DeleteSession tears down this session's engine-side temp
+			// files as well as its in-memory app.Sessions entry, so a
+			// locally-owned session is fully reaped in one call.
 			app.DeleteSession(id)
 		}
+		app.GcMetrics.RecordReaped(len(canceledIds))
 	}
 
 }
@@ -0,0 +1,84 @@
+// Copyright 2023 Ant Group Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import "sync/atomic"
+
+// GcMetrics accumulates lifetime counters for the GC leader/sweeper roles,
+// so an operator can tell healthy, rotating leadership apart from one stuck
+// in a renewal-failure loop, and see whether sweeps are actually reaping
+// anything.
+//
+// App owns the single shared instance as a `GcMetrics *GcMetrics` field,
+// constructed once via NewGcMetrics alongside App's other shared state, and
+// read by both StorageGc and SessionGc through that field.
+type GcMetrics struct {
+	leaseAcquisitions uint64
+	leaseRenewals     uint64
+	leaseExpirations  uint64
+	itemsReaped       uint64
+}
+
+// NewGcMetrics returns a zeroed GcMetrics, ready to be shared across the GC
+// leader and sweeper goroutines.
+func NewGcMetrics() *GcMetrics {
+	return &GcMetrics{}
+}
+
+// RecordAcquisition marks that this process became (or re-became) GC leader.
+func (m *GcMetrics) RecordAcquisition() {
+	atomic.AddUint64(&m.leaseAcquisitions, 1)
+}
+
+// RecordRenewal marks a successful heartbeat renewal of the GC lease.
+func (m *GcMetrics) RecordRenewal() {
+	atomic.AddUint64(&m.leaseRenewals, 1)
+}
+
+// RecordExpiration marks that this process lost, or failed to renew, the GC
+// lease and stepped down as leader.
+func (m *GcMetrics) RecordExpiration() {
+	atomic.AddUint64(&m.leaseExpirations, 1)
+}
+
+// RecordReaped adds n to the running count of items reaped by GC/sweeper
+// cycles. Negative n (no count available) is ignored.
+func (m *GcMetrics) RecordReaped(n int) {
+	if n <= 0 {
+		return
+	}
+	atomic.AddUint64(&m.itemsReaped, uint64(n))
+}
+
+// Acquisitions reports the lifetime count of GC lease acquisitions.
+func (m *GcMetrics) Acquisitions() uint64 {
+	return atomic.LoadUint64(&m.leaseAcquisitions)
+}
+
+// Renewals reports the lifetime count of successful GC lease renewals.
+func (m *GcMetrics) Renewals() uint64 {
+	return atomic.LoadUint64(&m.leaseRenewals)
+}
+
+// Expirations reports the lifetime count of GC lease expirations/step-downs.
+func (m *GcMetrics) Expirations() uint64 {
+	return atomic.LoadUint64(&m.leaseExpirations)
+}
+
+// ItemsReaped reports the lifetime count of items reaped across GC and
+// sweeper cycles.
+func (m *GcMetrics) ItemsReaped() uint64 {
+	return atomic.LoadUint64(&m.itemsReaped)
+}
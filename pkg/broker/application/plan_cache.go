@@ -0,0 +1,146 @@
+// Copyright 2023 Ant Group Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/secretflow/scql/pkg/proto-gen/scql"
+)
+
+// PlanCacheKey identifies a compiled plan that every party involved in the
+// query would agree on: the same normalized query run against the same
+// catalog and CCLs, with the same compile options, always compiles to the
+// same plan.
+type PlanCacheKey struct {
+	ProjectID           string
+	NormalizedQueryHash string
+	CatalogChecksum     string
+	CclChecksum         string
+	CompileOptsHash     string
+	// BindingHash hashes the bound QueryBinding's NormalizedHash, BoundQuery
+	// and Hints, or is empty when the query ran unbound. A bound and an
+	// unbound run of the same raw query, or runs under two different
+	// bindings, compile differently, so they must not share a cache entry.
+	BindingHash string
+}
+
+type planCacheEntry struct {
+	key       PlanCacheKey
+	plan      *pb.CompiledPlan
+	expiresAt time.Time
+	elem      *list.Element
+}
+
+// PlanCache is an LRU+TTL cache of compiled plans, keyed by PlanCacheKey.
+// It is shared across all sessions of a project so that repeating the same
+// query against an unchanged schema/CCL skips recompilation entirely.
+//
+// App owns the single shared instance as a `PlanCache *PlanCache` field,
+// constructed once via NewPlanCache alongside App's other shared state.
+type PlanCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[PlanCacheKey]*planCacheEntry
+	order    *list.List // front = most recently used
+
+	hits   uint64
+	misses uint64
+}
+
+// NewPlanCache creates a PlanCache holding at most capacity entries, each
+// valid for ttl after insertion.
+func NewPlanCache(capacity int, ttl time.Duration) *PlanCache {
+	return &PlanCache{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[PlanCacheKey]*planCacheEntry),
+		order:    list.New(),
+	}
+}
+
+// Get returns the cached plan for key if present and not expired.
+func (c *PlanCache) Get(key PlanCacheKey) (*pb.CompiledPlan, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		if ok {
+			c.removeLocked(entry)
+		}
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	c.order.MoveToFront(entry.elem)
+	atomic.AddUint64(&c.hits, 1)
+	return entry.plan, true
+}
+
+// Put inserts or refreshes the cached plan for key, evicting the least
+// recently used entry if the cache is at capacity.
+func (c *PlanCache) Put(key PlanCacheKey, plan *pb.CompiledPlan) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok {
+		entry.plan = plan
+		entry.expiresAt = time.Now().Add(c.ttl)
+		c.order.MoveToFront(entry.elem)
+		return
+	}
+	entry := &planCacheEntry{key: key, plan: plan, expiresAt: time.Now().Add(c.ttl)}
+	entry.elem = c.order.PushFront(entry)
+	c.entries[key] = entry
+	for c.capacity > 0 && len(c.entries) > c.capacity {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeLocked(oldest.Value.(*planCacheEntry))
+	}
+}
+
+// InvalidateProject drops every cached plan for projectID. Called whenever
+// a query runner re-prepares its tables and recomputes its checksums, so a
+// plan cached before a schema or CCL change is dropped; it does not compare
+// the old and new checksums first.
+func (c *PlanCache) InvalidateProject(projectID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range c.entries {
+		if entry.key.ProjectID == projectID {
+			c.removeLocked(entry)
+		}
+	}
+}
+
+func (c *PlanCache) removeLocked(entry *planCacheEntry) {
+	c.order.Remove(entry.elem)
+	delete(c.entries, entry.key)
+}
+
+// HitRate reports the cache's lifetime hit rate, exposed as a metric.
+func (c *PlanCache) HitRate() float64 {
+	hits := atomic.LoadUint64(&c.hits)
+	misses := atomic.LoadUint64(&c.misses)
+	total := hits + misses
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
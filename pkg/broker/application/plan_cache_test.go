@@ -0,0 +1,85 @@
+// Copyright 2023 Ant Group Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/secretflow/scql/pkg/proto-gen/scql"
+)
+
+func TestPlanCacheGetPutRoundTrip(t *testing.T) {
+	c := NewPlanCache(10, time.Minute)
+	key := PlanCacheKey{ProjectID: "p1", NormalizedQueryHash: "h1"}
+	if _, ok := c.Get(key); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+	plan := &pb.CompiledPlan{}
+	c.Put(key, plan)
+	got, ok := c.Get(key)
+	if !ok || got != plan {
+		t.Fatalf("expected cached plan back, got %v, %v", got, ok)
+	}
+}
+
+func TestPlanCacheExpiresByTTL(t *testing.T) {
+	c := NewPlanCache(10, time.Millisecond)
+	key := PlanCacheKey{ProjectID: "p1"}
+	c.Put(key, &pb.CompiledPlan{})
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.Get(key); ok {
+		t.Fatal("expected entry to expire")
+	}
+}
+
+func TestPlanCacheEvictsLRUAtCapacity(t *testing.T) {
+	c := NewPlanCache(2, time.Minute)
+	k1 := PlanCacheKey{ProjectID: "p1"}
+	k2 := PlanCacheKey{ProjectID: "p2"}
+	k3 := PlanCacheKey{ProjectID: "p3"}
+	c.Put(k1, &pb.CompiledPlan{})
+	c.Put(k2, &pb.CompiledPlan{})
+	c.Put(k3, &pb.CompiledPlan{})
+	if _, ok := c.Get(k1); ok {
+		t.Fatal("expected least recently used entry to be evicted")
+	}
+	if _, ok := c.Get(k2); !ok {
+		t.Fatal("expected k2 to still be cached")
+	}
+	if _, ok := c.Get(k3); !ok {
+		t.Fatal("expected k3 to still be cached")
+	}
+}
+
+func TestPlanCacheInvalidateProject(t *testing.T) {
+	c := NewPlanCache(10, time.Minute)
+	k1 := PlanCacheKey{ProjectID: "p1", NormalizedQueryHash: "a"}
+	k2 := PlanCacheKey{ProjectID: "p1", NormalizedQueryHash: "b"}
+	k3 := PlanCacheKey{ProjectID: "p2", NormalizedQueryHash: "a"}
+	c.Put(k1, &pb.CompiledPlan{})
+	c.Put(k2, &pb.CompiledPlan{})
+	c.Put(k3, &pb.CompiledPlan{})
+	c.InvalidateProject("p1")
+	if _, ok := c.Get(k1); ok {
+		t.Fatal("expected p1 entry to be invalidated")
+	}
+	if _, ok := c.Get(k2); ok {
+		t.Fatal("expected p1 entry to be invalidated")
+	}
+	if _, ok := c.Get(k3); !ok {
+		t.Fatal("expected p2 entry to survive invalidation of p1")
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2023 Ant Group Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	pb "github.com/secretflow/scql/pkg/proto-gen/scql"
+)
+
+// errKind classifies an error encountered while talking to a peer broker, so
+// a single Backoffer can decide whether to retry, give up, or just pass the
+// result straight back to the caller.
+type errKind int
+
+const (
+	// errKindRetryable covers transient conditions worth retrying within
+	// the backoff budget, e.g. SESSION_NOT_FOUND or network/5xx errors.
+	errKindRetryable errKind = iota
+	// errKindTerminalInformational covers errors that will not resolve by
+	// retrying but still carry a meaningful response for the caller, e.g.
+	// DATA_INCONSISTENCY.
+	errKindTerminalInformational
+	// errKindTerminal covers errors that should stop retrying immediately.
+	errKindTerminal
+)
+
+// Backoffer implements a TiDB-style exponential backoff with jitter: it
+// grows the retry interval geometrically between initialInterval and
+// maxInterval, and gives up once maxElapsed has been spent retrying.
+type Backoffer struct {
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	maxElapsed      time.Duration
+
+	current time.Duration
+	start   time.Time
+}
+
+// NewBackoffer creates a Backoffer. A maxElapsed of zero means "retry
+// forever" and is not currently used by any caller in this package.
+func NewBackoffer(initialInterval, maxInterval, maxElapsed time.Duration) *Backoffer {
+	return &Backoffer{
+		initialInterval: initialInterval,
+		maxInterval:     maxInterval,
+		maxElapsed:      maxElapsed,
+		current:         initialInterval,
+	}
+}
+
+// NextInterval returns how long to sleep before the next attempt, and false
+// once the elapsed retry budget has been exhausted.
+func (b *Backoffer) NextInterval() (time.Duration, bool) {
+	if b.start.IsZero() {
+		b.start = time.Now()
+	}
+	if b.maxElapsed > 0 && time.Since(b.start) >= b.maxElapsed {
+		return 0, false
+	}
+	interval := b.current
+	// full jitter: sleep anywhere in [0, interval] so retries from many
+	// callers don't all wake up in lockstep.
+	jittered := time.Duration(rand.Int63n(int64(interval) + 1))
+	b.current *= 2
+	if b.current > b.maxInterval {
+		b.current = b.maxInterval
+	}
+	return jittered, true
+}
+
+// ErrCircuitOpen is returned by CircuitBreaker.Allow when the breaker is
+// currently tripped for a peer.
+var ErrCircuitOpen = errors.New("circuit breaker open for peer")
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+)
+
+// peerBreaker tracks consecutive terminal failures for a single peer.
+type peerBreaker struct {
+	state        breakerState
+	failureCount int
+	openedAt     time.Time
+}
+
+// CircuitBreaker trips per-peer after tripThreshold consecutive terminal
+// failures and short-circuits further calls to that peer until cooldown has
+// elapsed, so a single unreachable party cannot stall a query by making
+// every caller wait out the full retry budget over and over.
+type CircuitBreaker struct {
+	mu            sync.Mutex
+	peers         map[string]*peerBreaker
+	tripThreshold int
+	cooldown      time.Duration
+}
+
+// NewCircuitBreaker creates a CircuitBreaker that trips after tripThreshold
+// consecutive terminal failures for a peer and stays open for cooldown.
+func NewCircuitBreaker(tripThreshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		peers:         make(map[string]*peerBreaker),
+		tripThreshold: tripThreshold,
+		cooldown:      cooldown,
+	}
+}
+
+// Allow returns ErrCircuitOpen if calls to peer are currently short-circuited.
+func (cb *CircuitBreaker) Allow(peer string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	breaker, exist := cb.peers[peer]
+	if !exist || breaker.state == breakerClosed {
+		return nil
+	}
+	if time.Since(breaker.openedAt) >= cb.cooldown {
+		// cooldown elapsed: close the breaker and reset its failure count,
+		// so calls go through again until tripThreshold new failures.
+		breaker.state = breakerClosed
+		breaker.failureCount = 0
+		return nil
+	}
+	return fmt.Errorf("%w: peer %s, retry after %s", ErrCircuitOpen, peer, cb.cooldown-time.Since(breaker.openedAt))
+}
+
+// RecordSuccess resets the failure count for peer.
+func (cb *CircuitBreaker) RecordSuccess(peer string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	delete(cb.peers, peer)
+}
+
+// RecordFailure registers a terminal failure for peer, tripping the breaker
+// once tripThreshold consecutive failures have been observed.
+func (cb *CircuitBreaker) RecordFailure(peer string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	breaker, exist := cb.peers[peer]
+	if !exist {
+		breaker = &peerBreaker{}
+		cb.peers[peer] = breaker
+	}
+	breaker.failureCount++
+	if breaker.failureCount >= cb.tripThreshold {
+		breaker.state = breakerOpen
+		breaker.openedAt = time.Now()
+	}
+}
+
+// classifyJobInfoError maps an ExchangeJobInfo outcome to an errKind so the
+// retry loop and circuit breaker can make a uniform decision regardless of
+// which RPC surfaced the error.
+func classifyJobInfoError(rpcErr error, statusCode int32) errKind {
+	if rpcErr != nil {
+		// transport-level failures (network blips, 5xx) are retryable
+		// within the backoff budget.
+		return errKindRetryable
+	}
+	switch pb.Code(statusCode) {
+	case pb.Code_SESSION_NOT_FOUND:
+		return errKindRetryable
+	case pb.Code_DATA_INCONSISTENCY:
+		return errKindTerminalInformational
+	case 0:
+		return errKindTerminalInformational
+	default:
+		return errKindTerminal
+	}
+}
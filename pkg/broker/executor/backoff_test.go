@@ -0,0 +1,89 @@
+// Copyright 2023 Ant Group Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/secretflow/scql/pkg/proto-gen/scql"
+)
+
+func TestCircuitBreakerTripsAfterThreshold(t *testing.T) {
+	cb := NewCircuitBreaker(3, time.Minute)
+	for i := 0; i < 2; i++ {
+		cb.RecordFailure("peer")
+		if err := cb.Allow("peer"); err != nil {
+			t.Fatalf("breaker should stay closed below threshold, got %v", err)
+		}
+	}
+	cb.RecordFailure("peer")
+	if err := cb.Allow("peer"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after reaching threshold, got %v", err)
+	}
+}
+
+func TestCircuitBreakerHalfOpensAfterCooldown(t *testing.T) {
+	cb := NewCircuitBreaker(1, 10*time.Millisecond)
+	cb.RecordFailure("peer")
+	if err := cb.Allow("peer"); err == nil {
+		t.Fatal("expected breaker to be open immediately after tripping")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := cb.Allow("peer"); err != nil {
+		t.Fatalf("expected breaker to half-open after cooldown, got %v", err)
+	}
+}
+
+func TestCircuitBreakerRecordSuccessResets(t *testing.T) {
+	cb := NewCircuitBreaker(2, time.Minute)
+	cb.RecordFailure("peer")
+	cb.RecordSuccess("peer")
+	cb.RecordFailure("peer")
+	if err := cb.Allow("peer"); err != nil {
+		t.Fatalf("RecordSuccess should reset the failure count, got %v", err)
+	}
+}
+
+func TestBackofferGrowsUntilMaxElapsed(t *testing.T) {
+	b := NewBackoffer(time.Millisecond, 4*time.Millisecond, 5*time.Millisecond)
+	var attempts int
+	for {
+		if _, ok := b.NextInterval(); !ok {
+			break
+		}
+		attempts++
+		if attempts > 1000 {
+			t.Fatal("backoff never exhausted its budget")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if attempts == 0 {
+		t.Fatal("expected at least one retry before exhausting the budget")
+	}
+}
+
+func TestClassifyJobInfoError(t *testing.T) {
+	if kind := classifyJobInfoError(errors.New("boom"), 0); kind != errKindRetryable {
+		t.Fatalf("transport errors should be retryable, got %v", kind)
+	}
+	if kind := classifyJobInfoError(nil, 0); kind != errKindTerminalInformational {
+		t.Fatalf("status 0 should be terminal-informational, got %v", kind)
+	}
+	if kind := classifyJobInfoError(nil, int32(pb.Code_DATA_INCONSISTENCY)); kind != errKindTerminalInformational {
+		t.Fatalf("DATA_INCONSISTENCY should be terminal-informational, got %v", kind)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 Ant Group Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"strings"
+	"time"
+
+	"github.com/secretflow/scql/pkg/broker/application"
+	"github.com/secretflow/scql/pkg/broker/storage"
+	pb "github.com/secretflow/scql/pkg/proto-gen/scql"
+)
+
+// normalizeQueryForBinding collapses whitespace so that cosmetically
+// different submissions of the same query text (different indentation,
+// trailing newline, ...) hash to the same binding.
+func normalizeQueryForBinding(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// lookupBinding looks up a QueryBinding previously registered for the
+// runner's current query, if any. A miss is not an error: most queries are
+// never bound and run with whatever query/hints the issuer submitted.
+func (r *QueryRunner) lookupBinding() (*storage.QueryBinding, error) {
+	s := r.session
+	txn := s.App.MetaMgr.CreateMetaTransaction()
+	var err error
+	defer func() {
+		err = txn.Finish(err)
+	}()
+	normalizedHash := hashBytes([]byte(normalizeQueryForBinding(s.ExecuteInfo.Query)))
+	binding, found, err := txn.GetQueryBindingByHash(s.ExecuteInfo.ProjectID, normalizedHash)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return binding, nil
+}
+
+// RegisterBinding pins rawQuery's normalized form to boundQuery and hints,
+// so future submissions of rawQuery reuse boundQuery/hints instead of
+// whatever the issuer resubmits.
+func RegisterBinding(session *application.Session, rawQuery, boundQuery string, hints *pb.CompileOptions) error {
+	txn := session.App.MetaMgr.CreateMetaTransaction()
+	var err error
+	defer func() {
+		err = txn.Finish(err)
+	}()
+	binding := &storage.QueryBinding{
+		ProjectID:      session.ExecuteInfo.ProjectID,
+		NormalizedHash: hashBytes([]byte(normalizeQueryForBinding(rawQuery))),
+		BoundQuery:     boundQuery,
+		Hints:          hints,
+		CreatedBy:      session.ExecuteInfo.Issuer.Code,
+		CreatedAt:      time.Now(),
+	}
+	err = txn.CreateQueryBinding(binding)
+	return err
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/secretflow/scql/pkg/broker/application"
 	"github.com/secretflow/scql/pkg/broker/constant"
@@ -46,10 +47,20 @@ type QueryRunner struct {
 	is     infoschema.InfoSchema
 	ccls   []*pb.SecurityConfig_ColumnControl
 	tables []storage.TableMeta
+	// binding is the QueryBinding registered for this query, if any; nil
+	// means the query runs unbound, with whatever query/hints the issuer
+	// submitted.
+	binding *storage.QueryBinding
 	// need update info/is/ccls
 	prepareAgain bool
 }
 
+// interBrokerBreaker trips per-peer after consecutive terminal failures on
+// inter-broker RPCs (ExchangeJobInfo, GetChecksumFromOtherParties). It is
+// process-wide rather than per-session because peer reachability is a
+// process-wide property that outlives any single query.
+var interBrokerBreaker = NewCircuitBreaker(5, 30*time.Second)
+
 func NewQueryRunner(session *application.Session) *QueryRunner {
 	return &QueryRunner{
 		session: session,
@@ -60,6 +71,7 @@ func (r *QueryRunner) Clear() {
 	r.info = nil
 	r.is = nil
 	r.ccls = nil
+	r.binding = nil
 }
 
 func (r *QueryRunner) GetEnginesInfo() *translator.EnginesInfo {
@@ -73,13 +85,36 @@ func (r *QueryRunner) SetPrepareAgain() {
 func (r *QueryRunner) CreateChecksum() (map[string]application.Checksum, error) {
 	s := r.session
 	checksumMap := make(map[string]application.Checksum)
+	// views are cross-party definitions: every data party must agree on their
+	// SQL text, so fold it into every party's checksum rather than only the
+	// owner of the underlying tables.
+	var views []storage.TableMeta
+	for _, t := range r.tables {
+		if t.Table.IsView {
+			views = append(views, t)
+		}
+	}
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].Table.TableName < views[j].Table.TableName
+	})
 	for _, p := range s.ExecuteInfo.DataParties {
 		tableSchemaCrypt := sha256.New()
 		cclCrypt := sha256.New()
+		// a binding substitutes the query/hints every party actually
+		// compiles, so it must be agreed on the same as table schema/CCL --
+		// fold it into the table schema checksum rather than introduce a
+		// third checksum channel.
+		if r.binding != nil {
+			tableSchemaCrypt.Write([]byte(r.binding.NormalizedHash))
+		}
 		tables := r.info.GetTablesByParty(p)
 		sort.Slice(tables, func(i, j int) bool {
 			return tables[i].String() < tables[j].String()
 		})
+		for _, v := range views {
+			tableSchemaCrypt.Write([]byte(v.Table.TableName))
+			tableSchemaCrypt.Write([]byte(v.Table.ViewQuery))
+		}
 		for _, t := range tables {
 			tableSchemaCrypt.Write([]byte(t.String()))
 			tableSchema, err := r.is.TableByName(model.NewCIStr(t.GetDbName()), model.NewCIStr(t.GetTableName()))
@@ -140,30 +175,43 @@ func (r *QueryRunner) ExchangeJobInfo(targetParty string) (*pb.ExchangeJobInfoRe
 	if err != nil {
 		return nil, fmt.Errorf("ExchangeJobInfoStub: %v", err)
 	}
+	if err := interBrokerBreaker.Allow(targetParty); err != nil {
+		return nil, fmt.Errorf("ExchangeJobInfo: %w", err)
+	}
 	response := &pb.ExchangeJobInfoResponse{}
+	backoff := NewBackoffer(session.App.Conf.ExchangeJobInfoRetryInterval, session.App.Conf.ExchangeJobInfoRetryInterval*8, session.App.Conf.ExchangeJobInfoRetryInterval*time.Duration(session.App.Conf.ExchangeJobInfoRetryTimes))
 	// retry to make sure that peer broker has created session
-	for i := 0; i < session.App.Conf.ExchangeJobInfoRetryTimes; i++ {
+	for {
 		err = executionInfo.InterStub.ExchangeJobInfo(url, req, response)
-		if err != nil {
-			return nil, fmt.Errorf("ExchangeJobInfoStub: %v", err)
-		}
-		if response.GetStatus().GetCode() == int32(pb.Code_SESSION_NOT_FOUND) {
-			if i < session.App.Conf.ExchangeJobInfoRetryTimes-1 {
-				time.Sleep(r.session.App.Conf.ExchangeJobInfoRetryInterval)
+		kind := classifyJobInfoError(err, response.GetStatus().GetCode())
+		switch kind {
+		case errKindRetryable:
+			interval, ok := backoff.NextInterval()
+			if !ok {
+				interBrokerBreaker.RecordFailure(targetParty)
+				if err != nil {
+					return nil, fmt.Errorf("ExchangeJobInfoStub: %v", err)
+				}
+				return nil, fmt.Errorf("ExchangeJobInfo: exhausted retry budget with party %s, last response %+v", targetParty, response)
 			}
+			time.Sleep(interval)
 			continue
-		}
-		if response.GetStatus().GetCode() == 0 {
+		case errKindTerminalInformational:
+			interBrokerBreaker.RecordSuccess(targetParty)
+			if err != nil {
+				return nil, fmt.Errorf("ExchangeJobInfoStub: %v", err)
+			}
+			// DATA_INCONSISTENCY is not retryable, but still a meaningful
+			// response the caller must see.
 			return response, nil
+		default:
+			interBrokerBreaker.RecordFailure(targetParty)
 		}
 		break
 	}
-	if response.Status == nil {
-		return nil, fmt.Errorf("err response from party %s; response %+v", targetParty, response)
-	}
-	if response.Status.Code == int32(pb.Code_DATA_INCONSISTENCY) {
-		return response, nil
-	}
+	// only errKindTerminal (a non-zero, non-DATA_INCONSISTENCY status with no
+	// transport error) falls through to here; both other kinds already
+	// returned above.
 	return nil, fmt.Errorf("failed to exchange job info with %s return error %+v", targetParty, response.Status)
 }
 
@@ -201,10 +249,31 @@ func (r *QueryRunner) prepareData(usedTableNames []string) (dataParties []string
 			return nil, nil, fmt.Errorf("prepareData: table %+v not found", notFoundTables)
 		}
 	}
+	// views carry no data of their own: recursively pull in the physical
+	// tables (possibly owned by other parties) they project over, so CCLs
+	// and compiler authz can be evaluated against the real columns.
+	r.tables, err = resolveViewDeps(session, txn, session.ExecuteInfo.ProjectID, r.tables, make(map[string]bool))
+	if err != nil {
+		return
+	}
 	var parties []string
 	party2Tables := make(map[string][]core.DbTable)
 	tableToRefs := make(map[core.DbTable]core.DbTable)
+	// CCL grants are keyed by physical table name, so collect those from the
+	// post-resolution table set (which now includes any view's underlying
+	// tables) rather than the original, pre-resolution usedTableNames --
+	// otherwise the physical tables behind a view would never pick up their
+	// CCL rows. The view's own name is not collected.
+	var cclTableNames []string
 	for _, t := range r.tables {
+		// a view is virtual: it has no owner or ref table of its own, only
+		// the physical tables it resolves to (already folded into r.tables
+		// above) participate in the engine-side execution plan or carry CCL
+		// grants.
+		if t.Table.IsView {
+			continue
+		}
+		cclTableNames = append(cclTableNames, t.Table.TableName)
 		parties = append(parties, t.Table.Owner)
 		if _, exist := party2Tables[t.Table.Owner]; !exist {
 			party2Tables[t.Table.Owner] = []core.DbTable{}
@@ -235,7 +304,7 @@ func (r *QueryRunner) prepareData(usedTableNames []string) (dataParties []string
 	r.info = translator.NewEnginesInfo(partyInfo, party2Tables)
 	r.info.UpdateTableToRefs(tableToRefs)
 	// get ccls
-	columnPrivs, err := txn.ListColumnConstraints(session.ExecuteInfo.ProjectID, usedTableNames, workParties)
+	columnPrivs, err := txn.ListColumnConstraints(session.ExecuteInfo.ProjectID, cclTableNames, workParties)
 	for _, columnPriv := range columnPrivs {
 		r.ccls = append(r.ccls, &pb.SecurityConfig_ColumnControl{
 			PartyCode:    columnPriv.DestParty,
@@ -260,6 +329,13 @@ func (r *QueryRunner) Prepare(usedTables []core.DbTable) (dataParties []string,
 	if err != nil {
 		return
 	}
+	// a registered binding, if any, pins the query/hints every party
+	// compiles; a lookup miss just leaves r.binding nil and the issuer's
+	// own query/hints are used unchanged.
+	r.binding, err = r.lookupBinding()
+	if err != nil {
+		return
+	}
 	// create info schema
 	r.is, err = r.CreateInfoSchema(r.tables)
 	if err != nil {
@@ -283,8 +359,9 @@ func (r *QueryRunner) CreateInfoSchema(tables []storage.TableMeta) (result infos
 			State:       model.StatePublic,
 			PKIsHandle:  false,
 		}
-		// TODO: support view
-
+		// for a view, tbl.Columns already holds the projected output schema
+		// persisted at CREATE VIEW time, so it can be built the same way as
+		// for a physical table.
 		for i, col := range tbl.Columns {
 			colTyp := strings.ToLower(col.DType)
 			defaultVal, err := infoschema.TypeDefaultValue(colTyp)
@@ -317,8 +394,14 @@ func (r *QueryRunner) CreateInfoSchema(tables []storage.TableMeta) (result infos
 func (r *QueryRunner) buildCompileQueryRequest() *pb.CompileQueryRequest {
 	s := r.session
 	catalog := buildCatalog(r.tables)
+	query := s.ExecuteInfo.Query
+	compileOpts := s.ExecuteInfo.CompileOpts
+	if r.binding != nil {
+		query = r.binding.BoundQuery
+		compileOpts = mergeCompileOpts(compileOpts, r.binding.Hints)
+	}
 	req := &pb.CompileQueryRequest{
-		Query:  s.ExecuteInfo.Query,
+		Query:  query,
 		DbName: s.ExecuteInfo.ProjectID,
 		Issuer: s.ExecuteInfo.Issuer,
 		// In p2p, `IssuerAsParticipant` is always true.
@@ -327,18 +410,83 @@ func (r *QueryRunner) buildCompileQueryRequest() *pb.CompileQueryRequest {
 			ColumnControlList: r.ccls,
 		},
 		Catalog:     catalog,
-		CompileOpts: s.ExecuteInfo.CompileOpts,
+		CompileOpts: compileOpts,
 	}
 	return req
 }
 
+// mergeCompileOpts overlays a binding's hints onto the issuer's own compile
+// options, preferring the binding's hints wherever it sets one: a binding is
+// only consulted when present, so it should win over the issuer's defaults.
+func mergeCompileOpts(base, hints *pb.CompileOptions) *pb.CompileOptions {
+	if hints == nil {
+		return base
+	}
+	if base == nil {
+		return hints
+	}
+	merged := proto.Clone(base).(*pb.CompileOptions)
+	proto.Merge(merged, hints)
+	return merged
+}
+
+// planCacheKey derives a application.PlanCacheKey from the runner's current
+// catalog, CCLs and compile options, so an unchanged query against an
+// unchanged schema/CCL can be served from application.App.PlanCache instead
+// of recompiled.
+func (r *QueryRunner) planCacheKey() (application.PlanCacheKey, error) {
+	s := r.session
+	catalogBytes, err := proto.Marshal(buildCatalog(r.tables))
+	if err != nil {
+		return application.PlanCacheKey{}, fmt.Errorf("planCacheKey: %w", err)
+	}
+	cclBytes, err := proto.Marshal(&pb.SecurityConfig{ColumnControlList: r.ccls})
+	if err != nil {
+		return application.PlanCacheKey{}, fmt.Errorf("planCacheKey: %w", err)
+	}
+	optsBytes, err := proto.Marshal(s.ExecuteInfo.CompileOpts)
+	if err != nil {
+		return application.PlanCacheKey{}, fmt.Errorf("planCacheKey: %w", err)
+	}
+	normalizedQuery := normalizeQueryForBinding(s.ExecuteInfo.Query)
+	var bindingHash string
+	if r.binding != nil {
+		// a binding changes the query/hints actually compiled, so two
+		// sessions with the same raw query but different bindings (or one
+		// bound, one not) must not share a cache entry.
+		hintBytes, err := proto.Marshal(r.binding.Hints)
+		if err != nil {
+			return application.PlanCacheKey{}, fmt.Errorf("planCacheKey: %w", err)
+		}
+		bindingBytes := []byte(r.binding.NormalizedHash + "\x00" + r.binding.BoundQuery + "\x00")
+		bindingHash = hashBytes(append(bindingBytes, hintBytes...))
+	}
+	return application.PlanCacheKey{
+		ProjectID:           s.ExecuteInfo.ProjectID,
+		NormalizedQueryHash: hashBytes([]byte(normalizedQuery)),
+		CatalogChecksum:     hashBytes(catalogBytes),
+		CclChecksum:         hashBytes(cclBytes),
+		CompileOptsHash:     hashBytes(optsBytes),
+		BindingHash:         bindingHash,
+	}, nil
+}
+
+func hashBytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf("%x", sum)
+}
+
 func buildCatalog(tables []storage.TableMeta) *pb.Catalog {
 	catalog := &pb.Catalog{}
 	for _, tbl := range tables {
 		tblEntry := &pb.TableEntry{
 			TableName: fmt.Sprintf("%s.%s", tbl.Table.ProjectID, tbl.Table.TableName),
-			// TODO: support view
-			IsView:   false,
+			IsView:    tbl.Table.IsView,
+			// NOTE: a view's ViewQuery text is not forwarded to the compiler
+			// here -- TableEntry in scql.proto has no view_query field yet.
+			// Until that's added and proto-gen/scql regenerated, the
+			// compiler only ever sees the physical tables resolveViewDeps
+			// already expanded a view into.
 			RefTable: tbl.Table.RefTable,
 			DbType:   tbl.Table.DBType,
 			Owner: &pb.PartyId{
@@ -475,13 +623,29 @@ func (r *QueryRunner) Execute(usedTables []core.DbTable) error {
 		if err := r.checkChecksum(); err != nil {
 			return err
 		}
+		// the schema/CCL may have changed underneath a stale cached plan.
+		s.App.PlanCache.InvalidateProject(s.ExecuteInfo.ProjectID)
 	}
 
-	compileReq := r.buildCompileQueryRequest()
-	intrpr := interpreter.NewInterpreter()
-	compiledPlan, err := intrpr.Compile(context.Background(), compileReq)
-	if err != nil {
-		return fmt.Errorf("failed to compile query to plan: %w", err)
+	cacheKey, keyErr := r.planCacheKey()
+	if keyErr != nil {
+		logrus.Warnf("planCacheKey: %s, skipping plan cache", keyErr)
+	}
+	compiledPlan, cached := (*pb.CompiledPlan)(nil), false
+	if keyErr == nil {
+		compiledPlan, cached = s.App.PlanCache.Get(cacheKey)
+	}
+	if !cached {
+		compileReq := r.buildCompileQueryRequest()
+		intrpr := interpreter.NewInterpreter()
+		var err error
+		compiledPlan, err = intrpr.Compile(context.Background(), compileReq)
+		if err != nil {
+			return fmt.Errorf("failed to compile query to plan: %w", err)
+		}
+		if keyErr == nil {
+			s.App.PlanCache.Put(cacheKey, compiledPlan)
+		}
 	}
 
 	logrus.Infof("Execution Plan:\n%s\n", compiledPlan.GetExplain().GetExeGraphDot())
@@ -553,12 +717,21 @@ func (r *QueryRunner) DryRun(usedTables []core.DbTable) error {
 	if err := r.checkChecksum(); err != nil {
 		return err
 	}
-	// 2. try compile query
+	// 2. try compile query, reusing a cached plan when one is available
+	cacheKey, keyErr := r.planCacheKey()
+	if keyErr == nil {
+		if _, cached := r.session.App.PlanCache.Get(cacheKey); cached {
+			return nil
+		}
+	}
 	compileReq := r.buildCompileQueryRequest()
 	intrpr := interpreter.NewInterpreter()
-	_, err := intrpr.Compile(context.TODO(), compileReq)
+	compiledPlan, err := intrpr.Compile(context.TODO(), compileReq)
 	if err != nil {
 		return fmt.Errorf("failed to compile query: %w", err)
 	}
+	if keyErr == nil {
+		r.session.App.PlanCache.Put(cacheKey, compiledPlan)
+	}
 	return nil
 }
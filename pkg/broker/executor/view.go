@@ -0,0 +1,164 @@
+// Copyright 2023 Ant Group Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/secretflow/scql/pkg/broker/application"
+	"github.com/secretflow/scql/pkg/broker/services/common"
+	"github.com/secretflow/scql/pkg/broker/storage"
+	"github.com/secretflow/scql/pkg/parser"
+	"github.com/secretflow/scql/pkg/parser/ast"
+	"github.com/secretflow/scql/pkg/util/sliceutil"
+)
+
+// maxViewDepth bounds recursive view resolution so a misbehaving or
+// maliciously nested chain of views cannot blow the stack; SCQL views are
+// not expected to nest anywhere near this deep in practice.
+const maxViewDepth = 8
+
+// CreateView persists a new view's catalog entry under projectID, so it can
+// later be resolved by resolveViewDeps like any other TableMeta. columns is
+// the view's projected output schema, already resolved by the caller (e.g.
+// a broker-facing CREATE VIEW DDL handler) against the underlying tables'
+// InfoSchema; without it the view would have no Columns and CreateInfoSchema
+// could never make it queryable.
+func CreateView(session *application.Session, tableName, viewQuery string, columns []storage.Column) error {
+	txn := session.App.MetaMgr.CreateMetaTransaction()
+	var err error
+	defer func() {
+		err = txn.Finish(err)
+	}()
+	err = txn.CreateView(session.ExecuteInfo.ProjectID, tableName, session.GetSelfPartyCode(), viewQuery, columns)
+	return err
+}
+
+// resolveViewDeps walks tables, and for every entry that is a view, parses
+// its stored SELECT text, fetches the tables it references (recursing into
+// any of those that are themselves views), and folds the physical tables it
+// bottoms out on into the returned set. Cyclic view definitions are
+// rejected. A view may reference tables owned by a party this broker
+// hasn't learned about yet, so a referenced table missing from the local
+// catalog is asked for from peers, the same way prepareData does for
+// top-level tables, before giving up.
+func resolveViewDeps(session *application.Session, txn storage.MetaTransaction, projectID string, tables []storage.TableMeta, visiting map[string]bool) ([]storage.TableMeta, error) {
+	return resolveViewDepsAtDepth(session, txn, projectID, tables, visiting, 0)
+}
+
+func resolveViewDepsAtDepth(session *application.Session, txn storage.MetaTransaction, projectID string, tables []storage.TableMeta, visiting map[string]bool, depth int) ([]storage.TableMeta, error) {
+	if depth > maxViewDepth {
+		return nil, fmt.Errorf("resolveViewDeps: view nesting exceeds max depth %d", maxViewDepth)
+	}
+	seen := make(map[string]bool)
+	var resolved []storage.TableMeta
+	for _, t := range tables {
+		key := fmt.Sprintf("%s.%s", t.Table.ProjectID, t.Table.TableName)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		resolved = append(resolved, t)
+		if !t.Table.IsView {
+			continue
+		}
+		if visiting[key] {
+			return nil, fmt.Errorf("resolveViewDeps: cyclic view definition detected on %s", key)
+		}
+		visiting[key] = true
+		refNames, err := extractTableNamesFromView(t.Table.ViewQuery)
+		if err != nil {
+			return nil, fmt.Errorf("resolveViewDeps: failed to parse view %s: %w", key, err)
+		}
+		refTables, notFound, err := txn.GetTableMetasByTableNames(projectID, refNames)
+		if err != nil {
+			return nil, err
+		}
+		if len(notFound) > 0 {
+			members, merr := txn.GetProjectMembers(projectID)
+			if merr != nil {
+				return nil, merr
+			}
+			askErr := common.AskProjectInfoFromParties(session.App, projectID, notFound, []string{}, sliceutil.Subtraction(members, []string{session.App.Conf.PartyCode}))
+			if askErr != nil {
+				logrus.Warningf("resolveViewDeps: ask not found tables %+v for view %s err: %s", notFound, key, askErr)
+			}
+			refTables, notFound, err = txn.GetTableMetasByTableNames(projectID, refNames)
+			if err != nil {
+				return nil, err
+			}
+			if len(notFound) > 0 {
+				return nil, fmt.Errorf("resolveViewDeps: view %s references unknown tables %+v", key, notFound)
+			}
+		}
+		refResolved, err := resolveViewDepsAtDepth(session, txn, projectID, refTables, visiting, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, rt := range refResolved {
+			rtKey := fmt.Sprintf("%s.%s", rt.Table.ProjectID, rt.Table.TableName)
+			if !seen[rtKey] {
+				seen[rtKey] = true
+				resolved = append(resolved, rt)
+			}
+		}
+		delete(visiting, key)
+	}
+	return resolved, nil
+}
+
+// tableNameCollector walks a view's SELECT statement and gathers every
+// table it references, so the broker can resolve the view's dependencies
+// before handing the query over to the compiler.
+type tableNameCollector struct {
+	names []string
+}
+
+func (c *tableNameCollector) Enter(n ast.Node) (ast.Node, bool) {
+	if t, ok := n.(*ast.TableName); ok {
+		c.names = append(c.names, t.Name.O)
+	}
+	return n, false
+}
+
+func (c *tableNameCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// extractTableNamesFromView parses a view's stored SELECT text and returns
+// the distinct table names it references.
+func extractTableNamesFromView(viewQuery string) ([]string, error) {
+	p := parser.New()
+	stmtNodes, _, err := p.Parse(viewQuery, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("extractTableNamesFromView: %w", err)
+	}
+	if len(stmtNodes) != 1 {
+		return nil, fmt.Errorf("extractTableNamesFromView: view query must contain exactly one statement, got %d", len(stmtNodes))
+	}
+	collector := &tableNameCollector{}
+	stmtNodes[0].Accept(collector)
+	seen := make(map[string]bool)
+	var names []string
+	for _, n := range collector.names {
+		if !seen[n] {
+			seen[n] = true
+			names = append(names, n)
+		}
+	}
+	return names, nil
+}
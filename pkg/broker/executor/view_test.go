@@ -0,0 +1,99 @@
+// Copyright 2023 Ant Group Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"testing"
+
+	"github.com/secretflow/scql/pkg/broker/storage"
+)
+
+// fakeMetaTxn is a minimal in-memory storage.MetaTransaction for exercising
+// resolveViewDeps without a real MetaMgr; every table referenced by a test
+// is known locally, so the peer-fallback path is never exercised here.
+type fakeMetaTxn struct {
+	tables map[string]storage.TableMeta
+}
+
+func (f *fakeMetaTxn) GetTableMetasByTableNames(projectID string, tableNames []string) ([]storage.TableMeta, []string, error) {
+	var found []storage.TableMeta
+	var notFound []string
+	for _, name := range tableNames {
+		meta, ok := f.tables[name]
+		if !ok {
+			notFound = append(notFound, name)
+			continue
+		}
+		found = append(found, meta)
+	}
+	return found, notFound, nil
+}
+
+func (f *fakeMetaTxn) GetProjectMembers(projectID string) ([]string, error) { return nil, nil }
+
+func (f *fakeMetaTxn) ListColumnConstraints(projectID string, tableNames []string, destParties []string) ([]storage.ColumnPriv, error) {
+	return nil, nil
+}
+
+func (f *fakeMetaTxn) CreateView(projectID, tableName, owner, viewQuery string, columns []storage.Column) error {
+	return nil
+}
+
+func (f *fakeMetaTxn) GetQueryBindingByHash(projectID, normalizedHash string) (*storage.QueryBinding, bool, error) {
+	return nil, false, nil
+}
+
+func (f *fakeMetaTxn) CreateQueryBinding(binding *storage.QueryBinding) error { return nil }
+
+func (f *fakeMetaTxn) Finish(err error) error { return err }
+
+func TestResolveViewDepsExpandsPhysicalTables(t *testing.T) {
+	txn := &fakeMetaTxn{tables: map[string]storage.TableMeta{
+		"t1": {Table: storage.Table{ProjectID: "p", TableName: "t1", Owner: "alice"}},
+		"v1": {Table: storage.Table{ProjectID: "p", TableName: "v1", IsView: true, ViewQuery: "select * from t1"}},
+	}}
+	resolved, err := resolveViewDeps(nil, txn, "p", []storage.TableMeta{txn.tables["v1"]}, make(map[string]bool))
+	if err != nil {
+		t.Fatalf("resolveViewDeps: %v", err)
+	}
+	var names []string
+	for _, tm := range resolved {
+		names = append(names, tm.Table.TableName)
+	}
+	if len(names) != 2 {
+		t.Fatalf("expected view + underlying table, got %+v", names)
+	}
+}
+
+func TestResolveViewDepsDetectsCycle(t *testing.T) {
+	txn := &fakeMetaTxn{tables: map[string]storage.TableMeta{
+		"v1": {Table: storage.Table{ProjectID: "p", TableName: "v1", IsView: true, ViewQuery: "select * from v2"}},
+		"v2": {Table: storage.Table{ProjectID: "p", TableName: "v2", IsView: true, ViewQuery: "select * from v1"}},
+	}}
+	_, err := resolveViewDeps(nil, txn, "p", []storage.TableMeta{txn.tables["v1"]}, make(map[string]bool))
+	if err == nil {
+		t.Fatal("expected cyclic view definition to be rejected")
+	}
+}
+
+func TestExtractTableNamesFromViewDedups(t *testing.T) {
+	names, err := extractTableNamesFromView("select a.x from t1 a join t1 b on a.x = b.x")
+	if err != nil {
+		t.Fatalf("extractTableNamesFromView: %v", err)
+	}
+	if len(names) != 1 || names[0] != "t1" {
+		t.Fatalf("expected deduped [t1], got %+v", names)
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2023 Ant Group Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storage holds the broker's view of persisted project metadata:
+// table/view catalog entries, column-level access grants and the cross-host
+// GC lease. MetaMgr is the single entry point executor and application code
+// use to read and mutate it.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/secretflow/scql/pkg/proto-gen/scql"
+)
+
+// Table is one catalog entry: either a physical table owned by Owner (this
+// party or a peer), or a view. A view carries no data of its own -- ViewQuery is the
+// CREATE VIEW ... AS <query> text persisted verbatim at creation time, so
+// every data party can re-parse and resolve it identically.
+type Table struct {
+	ProjectID string
+	TableName string
+	Owner     string
+	RefTable  string
+	DBType    string
+	IsView    bool
+	ViewQuery string
+}
+
+// Column describes one column of a Table.
+type Column struct {
+	ColumnName string
+	DType      string
+}
+
+// TableMeta pairs a Table with its column metadata.
+type TableMeta struct {
+	Table   Table
+	Columns []Column
+}
+
+// ColumnPriv is a single column-level access grant: DestParty may read
+// ColumnName of TableName under the Priv visibility level.
+type ColumnPriv struct {
+	ProjectID  string
+	TableName  string
+	ColumnName string
+	DestParty  string
+	Priv       string
+}
+
+// QueryBinding pins a normalized query template to a concrete bound query
+// and compiler hints, so repeated submissions of the same query shape reuse
+// a previously agreed-upon plan configuration instead of renegotiating it.
+type QueryBinding struct {
+	ProjectID      string
+	NormalizedHash string
+	BoundQuery     string
+	Hints          *pb.CompileOptions
+	CreatedBy      string
+	CreatedAt      time.Time
+}
+
+// GcLease is a fenced lease on GC leadership. Token increases monotonically
+// across HoldGcLock/RenewGcLock, so a stale former leader's late write can
+// always be told apart from the current holder's.
+type GcLease struct {
+	Owner     string
+	Token     uint64
+	ExpiresAt time.Time
+}
+
+// MetaTransaction scopes a set of metadata reads/writes; callers must call
+// Finish exactly once, passing the error (if any) that should cause the
+// transaction to roll back.
+type MetaTransaction interface {
+	GetTableMetasByTableNames(projectID string, tableNames []string) (tables []TableMeta, notFound []string, err error)
+	GetProjectMembers(projectID string) ([]string, error)
+	ListColumnConstraints(projectID string, tableNames []string, destParties []string) ([]ColumnPriv, error)
+	// CreateView persists a view's catalog entry, including its ViewQuery
+	// text and projected output columns, so it can later be resolved and
+	// queried like any other TableMeta.
+	CreateView(projectID, tableName, owner, viewQuery string, columns []Column) error
+	// GetQueryBindingByHash looks up the binding registered for
+	// normalizedHash in projectID, if any.
+	GetQueryBindingByHash(projectID, normalizedHash string) (binding *QueryBinding, found bool, err error)
+	// CreateQueryBinding persists binding, replacing any existing binding
+	// for the same ProjectID/NormalizedHash.
+	CreateQueryBinding(binding *QueryBinding) error
+	Finish(err error) error
+}
+
+// MetaMgr is the broker's handle onto persisted project metadata and the
+// cross-host GC lease. Table/view/binding reads and writes go through a
+// MetaTransaction from CreateMetaTransaction; GC lease and session-liveness
+// operations, being single statements with no need for multi-step rollback,
+// are called directly.
+type MetaMgr struct {
+	mu sync.Mutex
+
+	tables      map[string]map[string]TableMeta     // projectID -> tableName -> meta
+	members     map[string][]string                 // projectID -> party codes
+	columnPrivs map[string][]ColumnPriv             // projectID -> grants
+	bindings    map[string]map[string]*QueryBinding // projectID -> normalizedHash -> binding
+	canceledIds map[string]bool
+	results     map[string]time.Time // jobID -> createdAt, reaped by ClearExpiredResults
+
+	gcLockInitialized bool
+	gcLease           *GcLease
+	nextGcToken       uint64
+}
+
+// NewMetaMgr returns an empty MetaMgr.
+func NewMetaMgr() *MetaMgr {
+	return &MetaMgr{
+		tables:      make(map[string]map[string]TableMeta),
+		members:     make(map[string][]string),
+		columnPrivs: make(map[string][]ColumnPriv),
+		bindings:    make(map[string]map[string]*QueryBinding),
+		canceledIds: make(map[string]bool),
+		results:     make(map[string]time.Time),
+	}
+}
+
+type metaTxn struct {
+	mgr *MetaMgr
+}
+
+// CreateMetaTransaction opens a new MetaTransaction against this MetaMgr.
+func (m *MetaMgr) CreateMetaTransaction() MetaTransaction {
+	return &metaTxn{mgr: m}
+}
+
+func (t *metaTxn) GetTableMetasByTableNames(projectID string, tableNames []string) ([]TableMeta, []string, error) {
+	m := t.mgr
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var found []TableMeta
+	var notFound []string
+	project := m.tables[projectID]
+	for _, name := range tableNames {
+		meta, ok := project[name]
+		if !ok {
+			notFound = append(notFound, name)
+			continue
+		}
+		found = append(found, meta)
+	}
+	return found, notFound, nil
+}
+
+func (t *metaTxn) GetProjectMembers(projectID string) ([]string, error) {
+	m := t.mgr
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string{}, m.members[projectID]...), nil
+}
+
+func (t *metaTxn) ListColumnConstraints(projectID string, tableNames []string, destParties []string) ([]ColumnPriv, error) {
+	m := t.mgr
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tableSet := make(map[string]bool, len(tableNames))
+	for _, n := range tableNames {
+		tableSet[n] = true
+	}
+	partySet := make(map[string]bool, len(destParties))
+	for _, p := range destParties {
+		partySet[p] = true
+	}
+	var result []ColumnPriv
+	for _, priv := range m.columnPrivs[projectID] {
+		if tableSet[priv.TableName] && partySet[priv.DestParty] {
+			result = append(result, priv)
+		}
+	}
+	return result, nil
+}
+
+func (t *metaTxn) CreateView(projectID, tableName, owner, viewQuery string, columns []Column) error {
+	m := t.mgr
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tables[projectID]; !ok {
+		m.tables[projectID] = make(map[string]TableMeta)
+	}
+	if _, exists := m.tables[projectID][tableName]; exists {
+		return fmt.Errorf("CreateView: table %s already exists in project %s", tableName, projectID)
+	}
+	m.tables[projectID][tableName] = TableMeta{
+		Table: Table{
+			ProjectID: projectID,
+			TableName: tableName,
+			Owner:     owner,
+			IsView:    true,
+			ViewQuery: viewQuery,
+		},
+		Columns: columns,
+	}
+	return nil
+}
+
+func (t *metaTxn) GetQueryBindingByHash(projectID, normalizedHash string) (*QueryBinding, bool, error) {
+	m := t.mgr
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	binding, ok := m.bindings[projectID][normalizedHash]
+	return binding, ok, nil
+}
+
+func (t *metaTxn) CreateQueryBinding(binding *QueryBinding) error {
+	m := t.mgr
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.bindings[binding.ProjectID]; !ok {
+		m.bindings[binding.ProjectID] = make(map[string]*QueryBinding)
+	}
+	m.bindings[binding.ProjectID][binding.NormalizedHash] = binding
+	return nil
+}
+
+// Finish is a no-op commit/rollback: the in-memory store above has no
+// staged writes to flush or discard, so it just forwards err.
+func (t *metaTxn) Finish(err error) error {
+	return err
+}
+
+// InitGcLockIfNecessary ensures the GC lock row exists, creating it on first
+// use. Safe to call repeatedly.
+func (m *MetaMgr) InitGcLockIfNecessary() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gcLockInitialized = true
+	return nil
+}
+
+// HoldGcLock attempts to acquire (or renew, if owner already holds it) the
+// GC leadership lease for ttl, returning a fenced lease on success. It fails
+// if another owner currently holds an unexpired lease.
+func (m *MetaMgr) HoldGcLock(owner string, ttl time.Duration) (*GcLease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if m.gcLease != nil && m.gcLease.Owner != owner && now.Before(m.gcLease.ExpiresAt) {
+		return nil, fmt.Errorf("HoldGcLock: lease already held by %s until %s", m.gcLease.Owner, m.gcLease.ExpiresAt)
+	}
+	m.nextGcToken++
+	lease := &GcLease{Owner: owner, Token: m.nextGcToken, ExpiresAt: now.Add(ttl)}
+	m.gcLease = lease
+	return lease, nil
+}
+
+// RenewGcLock extends owner's lease for another ttl, so long as token still
+// matches the current lease and the lease hasn't already been taken over by
+// another owner. A mismatched token means this caller's lease already
+// lapsed and someone else won leadership; it must step down.
+func (m *MetaMgr) RenewGcLock(owner string, token uint64, ttl time.Duration) (*GcLease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.gcLease == nil || m.gcLease.Owner != owner || m.gcLease.Token != token {
+		return nil, fmt.Errorf("RenewGcLock: fencing token stale, lease no longer held by %s", owner)
+	}
+	m.nextGcToken++
+	m.gcLease.Token = m.nextGcToken
+	m.gcLease.ExpiresAt = time.Now().Add(ttl)
+	return m.gcLease, nil
+}
+
+// RecordResult tracks a job's result as created at createdAt, so a later
+// ClearExpiredResults sweep can tell it apart from one still within its
+// retention window.
+func (m *MetaMgr) RecordResult(jobID string, createdAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results[jobID] = createdAt
+}
+
+// ClearExpiredResults deletes every tracked result older than expireTime,
+// checking ctx between deletions so a lost GC lease halts the sweep
+// immediately instead of running to completion regardless. It returns the
+// number of results reaped before it stopped.
+func (m *MetaMgr) ClearExpiredResults(ctx context.Context, expireTime time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := time.Now().Add(-expireTime)
+	reaped := 0
+	for jobID, createdAt := range m.results {
+		if err := ctx.Err(); err != nil {
+			return reaped, err
+		}
+		if createdAt.Before(cutoff) {
+			delete(m.results, jobID)
+			reaped++
+		}
+	}
+	return reaped, nil
+}
+
+// CheckIdCanceled reports which of ids have been marked canceled.
+func (m *MetaMgr) CheckIdCanceled(ids []string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var canceled []string
+	for _, id := range ids {
+		if m.canceledIds[id] {
+			canceled = append(canceled, id)
+		}
+	}
+	return canceled, nil
+}
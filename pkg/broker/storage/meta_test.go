@@ -0,0 +1,84 @@
+// Copyright 2023 Ant Group Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueryBindingRoundTrip(t *testing.T) {
+	mgr := NewMetaMgr()
+	txn := mgr.CreateMetaTransaction()
+	if _, found, err := txn.GetQueryBindingByHash("p1", "h1"); err != nil || found {
+		t.Fatalf("expected miss before CreateQueryBinding, got found=%v err=%v", found, err)
+	}
+	binding := &QueryBinding{ProjectID: "p1", NormalizedHash: "h1", BoundQuery: "select 1"}
+	if err := txn.CreateQueryBinding(binding); err != nil {
+		t.Fatalf("CreateQueryBinding: %v", err)
+	}
+	got, found, err := txn.GetQueryBindingByHash("p1", "h1")
+	if err != nil || !found {
+		t.Fatalf("expected hit after CreateQueryBinding, got found=%v err=%v", found, err)
+	}
+	if got.BoundQuery != "select 1" {
+		t.Fatalf("expected BoundQuery %q, got %q", "select 1", got.BoundQuery)
+	}
+	if _, found, _ := txn.GetQueryBindingByHash("p2", "h1"); found {
+		t.Fatal("expected binding to be scoped to its own project")
+	}
+}
+
+func TestClearExpiredResultsOnlyReapsExpired(t *testing.T) {
+	mgr := NewMetaMgr()
+	now := time.Now()
+	mgr.RecordResult("old", now.Add(-time.Hour))
+	mgr.RecordResult("fresh", now)
+	reaped, err := mgr.ClearExpiredResults(context.Background(), 10*time.Minute)
+	if err != nil {
+		t.Fatalf("ClearExpiredResults: %v", err)
+	}
+	if reaped != 1 {
+		t.Fatalf("expected to reap 1 expired result, reaped %d", reaped)
+	}
+	if reaped, err := mgr.ClearExpiredResults(context.Background(), 10*time.Minute); err != nil || reaped != 0 {
+		t.Fatalf("expected fresh result to survive, reaped=%d err=%v", reaped, err)
+	}
+}
+
+func TestClearExpiredResultsStopsOnCanceledContext(t *testing.T) {
+	mgr := NewMetaMgr()
+	mgr.RecordResult("old", time.Now().Add(-time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := mgr.ClearExpiredResults(ctx, 10*time.Minute); err == nil {
+		t.Fatal("expected ClearExpiredResults to stop on a canceled context")
+	}
+}
+
+func TestRenewGcLockRejectsStaleToken(t *testing.T) {
+	mgr := NewMetaMgr()
+	lease, err := mgr.HoldGcLock("leader", time.Minute)
+	if err != nil {
+		t.Fatalf("HoldGcLock: %v", err)
+	}
+	if _, err := mgr.RenewGcLock("leader", lease.Token+1, time.Minute); err == nil {
+		t.Fatal("expected RenewGcLock to reject a stale fencing token")
+	}
+	if _, err := mgr.RenewGcLock("leader", lease.Token, time.Minute); err != nil {
+		t.Fatalf("RenewGcLock with the current token should succeed: %v", err)
+	}
+}